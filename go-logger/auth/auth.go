@@ -0,0 +1,166 @@
+// Package auth implements the optional write-protection for the logger
+// server: ed25519-signed JWTs and static per-source API keys, so the
+// server can be exposed to the internet without letting anyone write (or
+// delete) entries under a source they don't own.
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Principal is the authenticated identity behind a request. Its Name is
+// either the "sub" claim of a verified JWT or, for a matched API key,
+// "apikey:" followed by the key itself.
+type Principal struct {
+	Name    string
+	Sources []string
+}
+
+// Allows reports whether the principal is permitted to write under source.
+// A "*" entry in Sources allows every source; a principal with no
+// configured sources is allowed nothing (an empty scope fails closed).
+func (p Principal) Allows(source string) bool {
+	for _, s := range p.Sources {
+		if s == "*" || s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator verifies the Authorization: Bearer <jwt> and X-API-Key
+// headers used by write endpoints.
+type Authenticator struct {
+	pubKey  ed25519.PublicKey
+	apiKeys map[string]Principal
+}
+
+// New builds an Authenticator from a base64-encoded ed25519 public key
+// (JWT_PUBKEY) and a keys.yaml file mapping API keys to allowed sources.
+// Either source may be empty, in which case that credential type is never
+// accepted.
+func New(pubKeyB64, keysYAMLPath string) (*Authenticator, error) {
+	a := &Authenticator{apiKeys: map[string]Principal{}}
+
+	if pubKeyB64 != "" {
+		raw, err := base64.StdEncoding.DecodeString(pubKeyB64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_PUBKEY: %w", err)
+		}
+		if len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid JWT_PUBKEY: expected %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+		}
+		a.pubKey = ed25519.PublicKey(raw)
+	}
+
+	if keysYAMLPath != "" {
+		data, err := os.ReadFile(keysYAMLPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", keysYAMLPath, err)
+		}
+		var keys map[string][]string
+		if err := yaml.Unmarshal(data, &keys); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", keysYAMLPath, err)
+		}
+		for key, sources := range keys {
+			a.apiKeys[key] = Principal{Name: "apikey:" + key, Sources: sources}
+		}
+	}
+
+	return a, nil
+}
+
+// Authenticate inspects r for a Bearer JWT or X-API-Key header and returns
+// the resulting Principal. It returns an error if neither credential is
+// present or valid.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	if bearer := r.Header.Get("Authorization"); bearer != "" {
+		token := strings.TrimPrefix(bearer, "Bearer ")
+		if token == bearer {
+			return Principal{}, fmt.Errorf("Authorization header must use the Bearer scheme")
+		}
+		return a.authenticateJWT(token)
+	}
+
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		principal, ok := a.apiKeys[key]
+		if !ok {
+			return Principal{}, fmt.Errorf("unknown API key")
+		}
+		return principal, nil
+	}
+
+	return Principal{}, fmt.Errorf("missing Authorization or X-API-Key header")
+}
+
+// jwtClaims covers the subset of the JWT payload the logger understands.
+type jwtClaims struct {
+	Subject string   `json:"sub"`
+	Sources []string `json:"sources"`
+	Source  string   `json:"source"`
+	Expiry  int64    `json:"exp"`
+}
+
+func (a *Authenticator) authenticateJWT(token string) (Principal, error) {
+	if a.pubKey == nil {
+		return Principal{}, fmt.Errorf("JWT auth is not configured (JWT_PUBKEY unset)")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("malformed JWT")
+	}
+
+	header, err := decodeSegment(parts[0])
+	if err != nil {
+		return Principal{}, fmt.Errorf("malformed JWT header: %w", err)
+	}
+	var alg struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(header, &alg); err != nil || alg.Alg != "EdDSA" {
+		return Principal{}, fmt.Errorf("unsupported JWT algorithm %q, expected EdDSA", alg.Alg)
+	}
+
+	payload, err := decodeSegment(parts[1])
+	if err != nil {
+		return Principal{}, fmt.Errorf("malformed JWT payload: %w", err)
+	}
+
+	sig, err := decodeSegment(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("malformed JWT signature: %w", err)
+	}
+
+	signed := parts[0] + "." + parts[1]
+	if !ed25519.Verify(a.pubKey, []byte(signed), sig) {
+		return Principal{}, fmt.Errorf("invalid JWT signature")
+	}
+
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Principal{}, fmt.Errorf("malformed JWT claims: %w", err)
+	}
+	if claims.Expiry != 0 && time.Now().Unix() > claims.Expiry {
+		return Principal{}, fmt.Errorf("JWT has expired")
+	}
+
+	sources := claims.Sources
+	if claims.Source != "" {
+		sources = append(sources, claims.Source)
+	}
+	return Principal{Name: claims.Subject, Sources: sources}, nil
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPrincipalAllows(t *testing.T) {
+	cases := []struct {
+		name    string
+		sources []string
+		source  string
+		want    bool
+	}{
+		{"exact match", []string{"arduino-1"}, "arduino-1", true},
+		{"no match", []string{"arduino-1"}, "arduino-2", false},
+		{"wildcard", []string{"*"}, "anything", true},
+		{"empty scope fails closed", nil, "arduino-1", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := Principal{Sources: c.sources}
+			if got := p.Allows(c.source); got != c.want {
+				t.Errorf("Allows(%q) = %v, want %v", c.source, got, c.want)
+			}
+		})
+	}
+}
+
+func writeKeysFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write keys file: %v", err)
+	}
+	return path
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	keysFile := writeKeysFile(t, "mykey:\n  - arduino-1\n  - arduino-2\n")
+
+	a, err := New("", keysFile)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/write", nil)
+	r.Header.Set("X-API-Key", "mykey")
+	p, err := a.Authenticate(r)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if !p.Allows("arduino-1") || !p.Allows("arduino-2") || p.Allows("arduino-3") {
+		t.Fatalf("unexpected scope for matched key: %+v", p)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/write", nil)
+	r.Header.Set("X-API-Key", "wrongkey")
+	if _, err := a.Authenticate(r); err == nil {
+		t.Fatal("expected error for unknown API key")
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/write", nil)
+	if _, err := a.Authenticate(r); err == nil {
+		t.Fatal("expected error when no credential is present")
+	}
+}
+
+func signJWT(t *testing.T, priv ed25519.PrivateKey, claims jwtClaims) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": "EdDSA", "typ": "JWT"})
+	if err != nil {
+		t.Fatalf("marshal header: %v", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+
+	encode := base64.RawURLEncoding.EncodeToString
+	signed := encode(header) + "." + encode(payload)
+	sig := ed25519.Sign(priv, []byte(signed))
+	return signed + "." + encode(sig)
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	a, err := New(base64.StdEncoding.EncodeToString(pub), "")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	token := signJWT(t, priv, jwtClaims{Subject: "device-1", Sources: []string{"arduino-1"}})
+	r := httptest.NewRequest(http.MethodPost, "/write", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	p, err := a.Authenticate(r)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if p.Name != "device-1" || !p.Allows("arduino-1") || p.Allows("arduino-2") {
+		t.Fatalf("unexpected principal: %+v", p)
+	}
+
+	expired := signJWT(t, priv, jwtClaims{Subject: "device-1", Sources: []string{"arduino-1"}, Expiry: time.Now().Add(-time.Hour).Unix()})
+	r = httptest.NewRequest(http.MethodPost, "/write", nil)
+	r.Header.Set("Authorization", "Bearer "+expired)
+	if _, err := a.Authenticate(r); err == nil {
+		t.Fatal("expected error for expired JWT")
+	}
+
+	otherPub, otherPriv, err := ed25519.GenerateKey(nil)
+	_ = otherPub
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	forged := signJWT(t, otherPriv, jwtClaims{Subject: "device-1", Sources: []string{"arduino-1"}})
+	r = httptest.NewRequest(http.MethodPost, "/write", nil)
+	r.Header.Set("Authorization", "Bearer "+forged)
+	if _, err := a.Authenticate(r); err == nil {
+		t.Fatal("expected error for JWT signed by an untrusted key")
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/write", nil)
+	r.Header.Set("Authorization", token) // missing "Bearer " scheme
+	if _, err := a.Authenticate(r); err == nil {
+		t.Fatal("expected error when Authorization header omits the Bearer scheme")
+	}
+}
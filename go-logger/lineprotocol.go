@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spk57/myAnalytics.jl/go-logger/store"
+)
+
+// defaultSourceTagKey is the tag that maps to LogEntry.Source when parsing
+// line protocol and no other key is given. The key is configurable so
+// deployments that already tag readings under a different key (e.g. "host")
+// don't have to rewrite their agents.
+const defaultSourceTagKey = "source"
+
+// LineError describes a single line that failed to parse, numbered as it
+// appeared in the request body (1-indexed).
+type LineError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// tag is a single key=value pair from a line protocol tag set, kept in the
+// order it appeared on the line.
+type tag struct {
+	Key, Value string
+}
+
+// parseLineProtocol parses an InfluxDB-style line protocol body into
+// LogEntry rows. Each field on a line becomes its own entry named
+// "<measurement>.<field>"; sourceTagKey selects which tag becomes
+// LogEntry.Source, and any remaining tags are flattened into
+// LogEntry.Transaction as "k=v,k=v". Lines that fail to parse are skipped
+// and reported in errs rather than aborting the whole batch.
+func parseLineProtocol(body string, sourceTagKey string) (entries []store.LogEntry, errs []LineError) {
+	if sourceTagKey == "" {
+		sourceTagKey = defaultSourceTagKey
+	}
+
+	for i, rawLine := range strings.Split(body, "\n") {
+		lineNo := i + 1
+		line := strings.TrimRight(rawLine, "\r")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parsed, err := parseLine(line, sourceTagKey)
+		if err != nil {
+			errs = append(errs, LineError{Line: lineNo, Message: err.Error()})
+			continue
+		}
+		entries = append(entries, parsed...)
+	}
+	return entries, errs
+}
+
+func parseLine(line, sourceTagKey string) ([]store.LogEntry, error) {
+	tokens := splitUnescaped(line, ' ')
+	if len(tokens) < 2 || len(tokens) > 3 {
+		return nil, fmt.Errorf("expected \"measurement[,tags] fields [timestamp]\", got %d fields", len(tokens))
+	}
+
+	measurementAndTags := splitUnescaped(tokens[0], ',')
+	measurement := unescapeLP(measurementAndTags[0])
+	if measurement == "" {
+		return nil, fmt.Errorf("missing measurement")
+	}
+
+	var tags []tag
+	for _, t := range measurementAndTags[1:] {
+		kv := splitUnescaped(t, '=')
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("malformed tag %q", t)
+		}
+		tags = append(tags, tag{Key: unescapeLP(kv[0]), Value: unescapeLP(kv[1])})
+	}
+
+	source := ""
+	var transactionTags []string
+	for _, t := range tags {
+		if t.Key == sourceTagKey {
+			source = t.Value
+			continue
+		}
+		transactionTags = append(transactionTags, t.Key+"="+t.Value)
+	}
+
+	datetime := time.Now()
+	if len(tokens) == 3 {
+		ns, err := strconv.ParseInt(tokens[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %w", tokens[2], err)
+		}
+		datetime = time.Unix(0, ns).UTC()
+	}
+
+	fieldPairs := splitUnescaped(tokens[1], ',')
+	if len(fieldPairs) == 0 || (len(fieldPairs) == 1 && fieldPairs[0] == "") {
+		return nil, fmt.Errorf("missing fields")
+	}
+
+	entries := make([]store.LogEntry, 0, len(fieldPairs))
+	for _, f := range fieldPairs {
+		kv := splitUnescaped(f, '=')
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("malformed field %q", f)
+		}
+		entries = append(entries, store.LogEntry{
+			Transaction: strings.Join(transactionTags, ","),
+			Datetime:    datetime,
+			Name:        measurement + "." + unescapeLP(kv[0]),
+			Value:       parseFieldValue(kv[1]),
+			Source:      source,
+		})
+	}
+	return entries, nil
+}
+
+// parseFieldValue strips the integer suffix ("42i") and surrounding quotes
+// ("\"ok\"") line protocol uses to disambiguate field types; LogEntry
+// stores every value as a plain string anyway.
+func parseFieldValue(v string) string {
+	if strings.HasPrefix(v, `"`) && strings.HasSuffix(v, `"`) && len(v) >= 2 {
+		return unescapeLP(v[1 : len(v)-1])
+	}
+	return strings.TrimSuffix(v, "i")
+}
+
+// splitUnescaped splits s on sep, treating "\<sep>" as an escaped
+// separator that doesn't split. Escapes for any other character are left
+// untouched, since they belong to a later splitUnescaped pass (on a
+// different separator) or to the final unescapeLP call; consuming them
+// here would corrupt escapes meant for those later stages.
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	var cur strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) && s[i+1] == sep {
+			cur.WriteByte(sep)
+			i++
+			continue
+		}
+		if c == sep {
+			parts = append(parts, cur.String())
+			cur.Reset()
+			continue
+		}
+		cur.WriteByte(c)
+	}
+	parts = append(parts, cur.String())
+	return parts
+}
+
+// unescapeLP removes the backslash escapes ("\,", "\ ", "\=") that
+// splitUnescaped left in place because they were for a separator other than
+// the one it split on.
+func unescapeLP(s string) string {
+	return strings.NewReplacer(`\,`, ",", `\ `, " ", `\=`, "=").Replace(s)
+}
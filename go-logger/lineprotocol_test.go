@@ -0,0 +1,125 @@
+package main
+
+import "testing"
+
+func TestParseLineSingleField(t *testing.T) {
+	entries, err := parseLine("temp,source=arduino-1 celsius=23.5 1700000000000000000", "source")
+	if err != nil {
+		t.Fatalf("parseLine: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Name != "temp.celsius" || e.Value != "23.5" || e.Source != "arduino-1" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
+
+func TestParseLineMultiField(t *testing.T) {
+	entries, err := parseLine("weather,source=arduino-1 temp=23.5,humidity=45", "source")
+	if err != nil {
+		t.Fatalf("parseLine: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Name != "weather.temp" || entries[1].Name != "weather.humidity" {
+		t.Errorf("unexpected entry names: %q, %q", entries[0].Name, entries[1].Name)
+	}
+}
+
+func TestParseLineNonSourceTagsBecomeTransaction(t *testing.T) {
+	entries, err := parseLine("weather,source=arduino-1,room=kitchen temp=23.5", "source")
+	if err != nil {
+		t.Fatalf("parseLine: %v", err)
+	}
+	if entries[0].Transaction != "room=kitchen" {
+		t.Errorf("expected transaction %q, got %q", "room=kitchen", entries[0].Transaction)
+	}
+}
+
+func TestParseLineEscapedSeparators(t *testing.T) {
+	// tag1's value escapes the '=' that splitUnescaped(..., '=') would
+	// otherwise split on; de4ca33 fixed a bug where this line was rejected
+	// as a malformed tag.
+	entries, err := parseLine(`measurement,tag1=a\=b value=1`, "source")
+	if err != nil {
+		t.Fatalf("parseLine: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].Transaction != `tag1=a=b` {
+		t.Errorf("expected unescaped transaction %q, got %q", `tag1=a=b`, entries[0].Transaction)
+	}
+}
+
+func TestParseLineEscapedCommaAndSpace(t *testing.T) {
+	entries, err := parseLine(`measurement,tag1=a\,b name=val\ ue`, "source")
+	if err != nil {
+		t.Fatalf("parseLine: %v", err)
+	}
+	if entries[0].Transaction != `tag1=a,b` {
+		t.Errorf("expected unescaped tag value %q, got %q", `tag1=a,b`, entries[0].Transaction)
+	}
+	if entries[0].Value != "val ue" {
+		t.Errorf("expected unescaped field value %q, got %q", "val ue", entries[0].Value)
+	}
+}
+
+func TestParseLineQuotedStringField(t *testing.T) {
+	entries, err := parseLine(`status,source=arduino-1 state="ok"`, "source")
+	if err != nil {
+		t.Fatalf("parseLine: %v", err)
+	}
+	if entries[0].Value != "ok" {
+		t.Errorf("expected unquoted value %q, got %q", "ok", entries[0].Value)
+	}
+}
+
+func TestParseLineIntegerSuffixStripped(t *testing.T) {
+	entries, err := parseLine(`count,source=arduino-1 n=42i`, "source")
+	if err != nil {
+		t.Fatalf("parseLine: %v", err)
+	}
+	if entries[0].Value != "42" {
+		t.Errorf("expected %q, got %q", "42", entries[0].Value)
+	}
+}
+
+func TestParseLineMalformed(t *testing.T) {
+	cases := []string{
+		"",
+		"measurement",
+		"measurement,tag1 field=1",
+		"measurement field",
+		",source=arduino-1 field=1",
+	}
+	for _, line := range cases {
+		if _, err := parseLine(line, "source"); err == nil {
+			t.Errorf("parseLine(%q) expected error, got none", line)
+		}
+	}
+}
+
+func TestParseLineProtocolSkipsMalformedLinesAndComments(t *testing.T) {
+	body := "temp,source=a value=1\n# comment\n\nmeasurement\ntemp,source=b value=2\n"
+	entries, errs := parseLineProtocol(body, "source")
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if len(errs) != 1 || errs[0].Line != 4 {
+		t.Fatalf("expected a single error on line 4, got %+v", errs)
+	}
+}
+
+func TestParseLineCustomSourceTagKey(t *testing.T) {
+	entries, err := parseLine("temp,host=arduino-1 celsius=23.5", "host")
+	if err != nil {
+		t.Fatalf("parseLine: %v", err)
+	}
+	if entries[0].Source != "arduino-1" {
+		t.Errorf("expected source %q, got %q", "arduino-1", entries[0].Source)
+	}
+}
@@ -2,253 +2,334 @@
 package main
 
 import (
-	"encoding/csv"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
-)
 
-const (
-	logFile     = "logger.csv"
-	defaultPort = "8765"
+	"github.com/spk57/myAnalytics.jl/go-logger/auth"
+	"github.com/spk57/myAnalytics.jl/go-logger/metrics"
+	"github.com/spk57/myAnalytics.jl/go-logger/store"
 )
 
-// LogEntry represents a single log entry
-type LogEntry struct {
-	ID          int       `json:"id"`
-	Transaction string    `json:"transaction"`
-	Datetime    time.Time `json:"datetime"`
-	Name        string    `json:"name"`
-	Value       string    `json:"value"`
-	Source      string    `json:"source"`
-	CreatedAt   time.Time `json:"created_at"`
-}
+const defaultPort = "8765"
 
-// Logger handles all log operations with thread safety
-type Logger struct {
-	mu       sync.RWMutex
-	filePath string
+// defaultDSN is used when STORAGE_DSN is unset, preserving the original
+// CSV-on-disk behavior. LOG_FILE still overrides the CSV path for
+// backwards compatibility with existing deployments.
+func defaultDSN() string {
+	logFile := os.Getenv("LOG_FILE")
+	if logFile == "" {
+		logFile = "logger.csv"
+	}
+	return "csv://" + logFile
 }
 
-// NewLogger creates a new Logger instance and initializes the CSV file
-func NewLogger(filePath string) (*Logger, error) {
-	l := &Logger{filePath: filePath}
-	if err := l.initFile(); err != nil {
-		return nil, err
-	}
-	return l, nil
+// subscriberBufferSize bounds how many entries a /logs/stream subscriber
+// can fall behind by before the broker starts dropping its oldest
+// unconsumed events to make room for new ones.
+const subscriberBufferSize = 64
+
+// subscriber is one live /logs/stream connection's interest registration.
+type subscriber struct {
+	ch     chan store.LogEntry
+	source string
+	name   string
 }
 
-// initFile creates the CSV file with headers if it doesn't exist
-func (l *Logger) initFile() error {
-	if _, err := os.Stat(l.filePath); os.IsNotExist(err) {
-		file, err := os.Create(l.filePath)
-		if err != nil {
-			return fmt.Errorf("failed to create log file: %w", err)
-		}
-		defer file.Close()
+// Logger handles all log operations, delegating persistence to a Store and
+// fanning every successful write out to any live /logs/stream subscribers.
+type Logger struct {
+	store store.Store
 
-		writer := csv.NewWriter(file)
-		headers := []string{"id", "transaction", "datetime", "name", "value", "source", "created_at"}
-		if err := writer.Write(headers); err != nil {
-			return fmt.Errorf("failed to write headers: %w", err)
-		}
-		writer.Flush()
-	}
-	return nil
+	subMu sync.Mutex
+	subs  []*subscriber
+
+	latestMu sync.Mutex
+	latest   map[string]store.LogEntry // keyed by name+"\x00"+source
 }
 
-// readAllEntries reads all entries from the CSV file
-func (l *Logger) readAllEntries() ([]LogEntry, error) {
-	file, err := os.Open(l.filePath)
+// NewLogger opens the Store identified by dsn.
+func NewLogger(dsn string) (*Logger, error) {
+	s, err := store.New(dsn)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	return &Logger{store: s, latest: make(map[string]store.LogEntry)}, nil
+}
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+// AddEntry adds a new log entry, optionally recording the authenticated
+// principal that wrote it.
+func (l *Logger) AddEntry(datetime time.Time, transaction, name, value, source, principal string) (int, error) {
+	entry := store.LogEntry{
+		Transaction: transaction,
+		Datetime:    datetime,
+		Name:        name,
+		Value:       value,
+		Source:      source,
+		Principal:   principal,
+	}
+
+	start := time.Now()
+	id, err := l.store.AddEntry(entry)
+	metrics.AddEntryDuration.Observe(time.Since(start).Seconds())
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
+	metrics.EntriesTotal.Inc()
 
-	var entries []LogEntry
-	for i, record := range records {
-		if i == 0 { // skip header
-			continue
-		}
-		if len(record) < 7 {
-			continue
-		}
-
-		id, _ := strconv.Atoi(record[0])
-		datetime, _ := time.Parse(time.RFC3339, record[2])
-		createdAt, _ := time.Parse(time.RFC3339, record[6])
-
-		entries = append(entries, LogEntry{
-			ID:          id,
-			Transaction: record[1],
-			Datetime:    datetime,
-			Name:        record[3],
-			Value:       record[4],
-			Source:      record[5],
-			CreatedAt:   createdAt,
-		})
-	}
-	return entries, nil
+	entry.ID = id
+	entry.CreatedAt = time.Now()
+	l.publish(entry)
+	return id, nil
 }
 
-// AddEntry adds a new log entry
-func (l *Logger) AddEntry(datetime time.Time, transaction, name, value, source string) (int, error) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
-	entries, err := l.readAllEntries()
+// AddEntries writes a batch of entries under a single lock acquisition and
+// a single flush, for high-throughput ingest paths like /write.
+func (l *Logger) AddEntries(entries []store.LogEntry) ([]int, error) {
+	start := time.Now()
+	ids, err := l.store.AddEntries(entries)
+	metrics.AddEntryDuration.Observe(time.Since(start).Seconds())
 	if err != nil {
-		return 0, err
+		return ids, err
 	}
+	metrics.EntriesTotal.Add(uint64(len(ids)))
 
-	nextID := 1
-	if len(entries) > 0 {
-		maxID := 0
-		for _, e := range entries {
-			if e.ID > maxID {
-				maxID = e.ID
-			}
+	for i, id := range ids {
+		entry := entries[i]
+		entry.ID = id
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = time.Now()
 		}
-		nextID = maxID + 1
-	}
-
-	file, err := os.OpenFile(l.filePath, os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return 0, err
+		l.publish(entry)
 	}
-	defer file.Close()
+	return ids, nil
+}
 
-	writer := csv.NewWriter(file)
-	record := []string{
-		strconv.Itoa(nextID),
-		transaction,
-		datetime.Format(time.RFC3339),
-		name,
-		value,
-		source,
-		time.Now().Format(time.RFC3339),
-	}
-	if err := writer.Write(record); err != nil {
-		return 0, err
+// Subscribe registers interest in newly-added entries matching the given
+// source/name filters (empty means "any"). The caller must invoke the
+// returned cancel func once done to release the subscription.
+func (l *Logger) Subscribe(source, name string) (<-chan store.LogEntry, func()) {
+	sub := &subscriber{ch: make(chan store.LogEntry, subscriberBufferSize), source: source, name: name}
+
+	l.subMu.Lock()
+	l.subs = append(l.subs, sub)
+	l.subMu.Unlock()
+
+	cancel := func() {
+		l.subMu.Lock()
+		defer l.subMu.Unlock()
+		for i, s := range l.subs {
+			if s == sub {
+				l.subs = append(l.subs[:i], l.subs[i+1:]...)
+				break
+			}
+		}
+		close(sub.ch)
 	}
-	writer.Flush()
-
-	return nextID, nil
+	return sub.ch, cancel
 }
 
-// GetEntries retrieves log entries with optional filtering
-func (l *Logger) GetEntries(limit, offset int, source, name string) ([]LogEntry, int, error) {
-	l.mu.RLock()
-	defer l.mu.RUnlock()
+// publish fans entry out to every matching subscriber. A subscriber whose
+// buffer is full has its oldest unconsumed entry dropped to make room,
+// trading history for keeping the stream live.
+func (l *Logger) publish(entry store.LogEntry) {
+	l.latestMu.Lock()
+	l.latest[entry.Name+"\x00"+entry.Source] = entry
+	l.latestMu.Unlock()
 
-	entries, err := l.readAllEntries()
-	if err != nil {
-		return nil, 0, err
-	}
+	l.subMu.Lock()
+	defer l.subMu.Unlock()
 
-	// Apply filters
-	var filtered []LogEntry
-	for _, e := range entries {
-		if source != "" && e.Source != source {
+	for _, sub := range l.subs {
+		if sub.source != "" && sub.source != entry.Source {
 			continue
 		}
-		if name != "" && e.Name != name {
+		if sub.name != "" && sub.name != entry.Name {
 			continue
 		}
-		filtered = append(filtered, e)
-	}
 
-	total := len(filtered)
-
-	// Apply pagination
-	if offset >= total {
-		return []LogEntry{}, total, nil
-	}
-	end := offset + limit
-	if end > total {
-		end = total
+		select {
+		case sub.ch <- entry:
+		default:
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- entry:
+			default:
+			}
+		}
 	}
+}
 
-	return filtered[offset:end], total, nil
+// GetEntries retrieves log entries with optional filtering. from/to bound
+// Datetime (inclusive) and are ignored when zero.
+func (l *Logger) GetEntries(limit, offset int, source, name string, from, to time.Time) ([]store.LogEntry, int, error) {
+	return l.store.Query(store.Query{
+		Source: source,
+		Name:   name,
+		From:   from,
+		To:     to,
+		Limit:  limit,
+		Offset: offset,
+	})
 }
 
-// ClearEntries removes all log entries
-func (l *Logger) ClearEntries() error {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+// ClearEntries removes every entry, or only those matching sources if any
+// are given.
+func (l *Logger) ClearEntries(sources ...string) error {
+	return l.store.Clear(sources...)
+}
 
-	file, err := os.Create(l.filePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// LatestEntries returns the most recently published entry for every
+// (name, source) pair seen since startup, for use by /metrics gauges.
+func (l *Logger) LatestEntries() []store.LogEntry {
+	l.latestMu.Lock()
+	defer l.latestMu.Unlock()
 
-	writer := csv.NewWriter(file)
-	headers := []string{"id", "transaction", "datetime", "name", "value", "source", "created_at"}
-	if err := writer.Write(headers); err != nil {
-		return err
+	entries := make([]store.LogEntry, 0, len(l.latest))
+	for _, e := range l.latest {
+		entries = append(entries, e)
 	}
-	writer.Flush()
-	return nil
+	return entries
 }
 
-// GetStats returns statistics about the log entries
+// GetStats returns statistics about the log entries.
 func (l *Logger) GetStats() (map[string]interface{}, error) {
-	l.mu.RLock()
-	defer l.mu.RUnlock()
-
-	entries, err := l.readAllEntries()
+	stats, err := l.store.Stats()
 	if err != nil {
 		return nil, err
 	}
 
-	sourcesMap := make(map[string]bool)
-	namesMap := make(map[string]bool)
-
-	for _, e := range entries {
-		sourcesMap[e.Source] = true
-		namesMap[e.Name] = true
-	}
-
-	sources := make([]string, 0, len(sourcesMap))
-	for s := range sourcesMap {
-		sources = append(sources, s)
-	}
-
-	names := make([]string, 0, len(namesMap))
-	for n := range namesMap {
-		names = append(names, n)
-	}
-
 	return map[string]interface{}{
 		"success":        true,
-		"total_entries":  len(entries),
-		"unique_sources": len(sources),
-		"unique_names":   len(names),
-		"sources":        sources,
-		"names":          names,
+		"total_entries":  stats.TotalEntries,
+		"unique_sources": len(stats.Sources),
+		"unique_names":   len(stats.Names),
+		"sources":        stats.Sources,
+		"names":          stats.Names,
 	}, nil
 }
 
 // API Server
 type Server struct {
 	logger *Logger
+	// auth is nil when the server was started without -auth.
+	auth             *auth.Authenticator
+	authReadRequired bool
+}
+
+// NewServer wires up a Server. When authenticator is non-nil, every
+// registered handler is wrapped in authMiddleware: POST/DELETE requests
+// always require a valid credential, and GET requests require one too if
+// authReadRequired is set.
+func NewServer(logger *Logger, authenticator *auth.Authenticator, authReadRequired bool) *Server {
+	return &Server{logger: logger, auth: authenticator, authReadRequired: authReadRequired}
 }
 
-func NewServer(logger *Logger) *Server {
-	return &Server{logger: logger}
+type principalCtxKey struct{}
+
+// principalFromContext returns the principal authMiddleware attached to the
+// request, or the zero Principal if auth is disabled.
+func principalFromContext(ctx context.Context) auth.Principal {
+	p, _ := ctx.Value(principalCtxKey{}).(auth.Principal)
+	return p
+}
+
+// authMiddleware enforces the -auth / -auth-read policy before calling
+// next, and attaches the resolved Principal to the request context so
+// handlers can scope writes to it. alwaysRequired should be true for GET
+// handlers that write despite the method (e.g. handleQuickLog), since
+// otherwise a GET write path would only be gated by -auth-read instead of
+// -auth like every other write.
+func (s *Server) authMiddleware(next http.HandlerFunc, alwaysRequired bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.auth == nil || r.Method == http.MethodOptions {
+			next(w, r)
+			return
+		}
+
+		required := alwaysRequired || r.Method != http.MethodGet || s.authReadRequired
+		if !required {
+			next(w, r)
+			return
+		}
+
+		principal, err := s.auth.Authenticate(r)
+		if err != nil {
+			enableCORS(w)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"message": "unauthorized: " + err.Error(),
+			})
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), principalCtxKey{}, principal)))
+	}
+}
+
+// authorizeSource checks that the request's principal is scoped to source.
+// It is a no-op (always allowed) when auth is disabled.
+func (s *Server) authorizeSource(w http.ResponseWriter, r *http.Request, source string) bool {
+	if s.auth == nil {
+		return true
+	}
+	principal := principalFromContext(r.Context())
+	if !principal.Allows(source) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"message": fmt.Sprintf("principal %q is not authorized to write source %q", principal.Name, source),
+		})
+		return false
+	}
+	return true
+}
+
+// statusRecorder wraps http.ResponseWriter to capture the status code a
+// handler actually wrote, for metricsMiddleware.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush forwards to the wrapped writer so streaming handlers such as
+// handleLogsStream still see an http.Flusher.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// metricsMiddleware records one logger_http_requests_total observation per
+// request, keyed by the route it was registered under, the HTTP method and
+// the status code the handler wrote (200 if the handler never calls
+// WriteHeader explicitly).
+func metricsMiddleware(path string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+		metrics.HTTPRequestsTotal.WithLabelValues(path, r.Method, strconv.Itoa(rec.status)).Inc()
+	}
 }
 
 // AddLogRequest is the request body for adding a log entry
@@ -357,7 +438,12 @@ func (s *Server) addLogEntry(w http.ResponseWriter, r *http.Request) {
 		valueStr = fmt.Sprintf("%v", v)
 	}
 
-	id, err := s.logger.AddEntry(dt, req.Transaction, req.Name, valueStr, req.Source)
+	if !s.authorizeSource(w, r, req.Source) {
+		return
+	}
+
+	principal := principalFromContext(r.Context())
+	id, err := s.logger.AddEntry(dt, req.Transaction, req.Name, valueStr, req.Source, principal.Name)
 	if err != nil {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
@@ -373,6 +459,19 @@ func (s *Server) addLogEntry(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// parseTimeParam parses an RFC3339 or YYYY-MM-DDTHH:MM:SS timestamp, the
+// same formats addLogEntry accepts for "datetime". An empty string returns
+// the zero time (unbounded) with no error.
+func parseTimeParam(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02T15:04:05", s)
+}
+
 func (s *Server) getLogEntries(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 
@@ -393,7 +492,24 @@ func (s *Server) getLogEntries(w http.ResponseWriter, r *http.Request) {
 	source := query.Get("source")
 	name := query.Get("name")
 
-	entries, total, err := s.logger.GetEntries(limit, offset, source, name)
+	from, err := parseTimeParam(query.Get("from"))
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"message": "invalid from: " + err.Error(),
+		})
+		return
+	}
+	to, err := parseTimeParam(query.Get("to"))
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"message": "invalid to: " + err.Error(),
+		})
+		return
+	}
+
+	entries, total, err := s.logger.GetEntries(limit, offset, source, name, from, to)
 	if err != nil {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
@@ -411,8 +527,28 @@ func (s *Server) getLogEntries(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// clearLogEntries wipes everything when auth is disabled or the principal
+// carries the "*" wildcard, and otherwise restricts the wipe to the
+// principal's own authorized sources, so a credential scoped to one source
+// can never erase another source's history.
 func (s *Server) clearLogEntries(w http.ResponseWriter, r *http.Request) {
-	if err := s.logger.ClearEntries(); err != nil {
+	var sources []string
+	if s.auth != nil {
+		principal := principalFromContext(r.Context())
+		if len(principal.Sources) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"message": fmt.Sprintf("principal %q is not authorized to clear any source", principal.Name),
+			})
+			return
+		}
+		if !principal.Allows("*") {
+			sources = principal.Sources
+		}
+	}
+
+	if err := s.logger.ClearEntries(sources...); err != nil {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
 			"message": "Failed to clear entries: " + err.Error(),
@@ -420,9 +556,13 @@ func (s *Server) clearLogEntries(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	message := "All log entries cleared"
+	if len(sources) > 0 {
+		message = fmt.Sprintf("Log entries cleared for source(s): %s", strings.Join(sources, ", "))
+	}
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
-		"message": "All log entries cleared",
+		"message": message,
 	})
 }
 
@@ -477,7 +617,12 @@ func (s *Server) handleQuickLog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := s.logger.AddEntry(time.Now(), transaction, name, value, source)
+	if !s.authorizeSource(w, r, source) {
+		return
+	}
+
+	principal := principalFromContext(r.Context())
+	id, err := s.logger.AddEntry(time.Now(), transaction, name, value, source, principal.Name)
 	if err != nil {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": false,
@@ -493,6 +638,91 @@ func (s *Server) handleQuickLog(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleWrite accepts a batch of InfluxDB-style line protocol, letting
+// telegraf/collectd agents and microcontrollers push many readings in one
+// request instead of one round-trip per reading.
+// Example: POST /write  (body: "temp,source=arduino-1 celsius=23.5 1700000000000000000")
+func (s *Server) handleWrite(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"success":false,"message":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := readRequestBody(r)
+	if err != nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"message": "Failed to read request body: " + err.Error(),
+		})
+		return
+	}
+
+	sourceTagKey := r.URL.Query().Get("source_tag")
+	entries, lineErrs := parseLineProtocol(string(body), sourceTagKey)
+
+	if s.auth != nil {
+		principal := principalFromContext(r.Context())
+		authorized := entries[:0]
+		for _, e := range entries {
+			if !principal.Allows(e.Source) {
+				lineErrs = append(lineErrs, LineError{
+					Message: fmt.Sprintf("principal %q is not authorized to write source %q", principal.Name, e.Source),
+				})
+				continue
+			}
+			e.Principal = principal.Name
+			authorized = append(authorized, e)
+		}
+		entries = authorized
+	}
+
+	var written int
+	if len(entries) > 0 {
+		ids, err := s.logger.AddEntries(entries)
+		written = len(ids)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":       false,
+				"message":       "Failed to write entries: " + err.Error(),
+				"line_errors":   lineErrs,
+				"lines_failed":  len(lineErrs),
+				"lines_written": written,
+			})
+			return
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":       len(lineErrs) == 0,
+		"lines_written": written,
+		"lines_failed":  len(lineErrs),
+		"line_errors":   lineErrs,
+	})
+}
+
+// readRequestBody returns the request body, transparently gunzipping it
+// when Content-Encoding: gzip is set.
+func readRequestBody(r *http.Request) ([]byte, error) {
+	defer r.Body.Close()
+
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gz.Close()
+		return io.ReadAll(gz)
+	}
+	return io.ReadAll(r.Body)
+}
+
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	enableCORS(w)
 	w.Header().Set("Content-Type", "application/json")
@@ -502,31 +732,94 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleMetrics renders process counters/histograms plus a gauge per
+// (name, source) derived from the most recent numeric LogEntry value, in
+// Prometheus text exposition format.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		enableCORS(w)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"success":false,"message":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metrics.WritePrometheus(w)
+	writeEntryGauges(w, s.logger.LatestEntries())
+}
+
+// isEnabled parses the handful of truthy spellings used by this server's
+// boolean env vars (AUTH, AUTH_READ, LOG_COMPRESS, ...).
+func isEnabled(v string) bool {
+	return v == "true" || v == "1" || v == "yes"
+}
+
 func main() {
+	authFlag := flag.Bool("auth", isEnabled(os.Getenv("AUTH")), "require a credential for POST/DELETE requests")
+	authReadFlag := flag.Bool("auth-read", isEnabled(os.Getenv("AUTH_READ")), "with -auth, also require a credential for GET requests")
+	flag.Parse()
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = defaultPort
 	}
 
-	logFilePath := os.Getenv("LOG_FILE")
-	if logFilePath == "" {
-		logFilePath = logFile
+	dsn := os.Getenv("STORAGE_DSN")
+	if dsn == "" {
+		dsn = defaultDSN()
 	}
 
-	logger, err := NewLogger(logFilePath)
+	logger, err := NewLogger(dsn)
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 
-	server := NewServer(logger)
+	migrateFrom := os.Getenv("LOG_FILE")
+	if migrateFrom == "" {
+		migrateFrom = "logger.csv"
+	}
+	if dsn != defaultDSN() {
+		imported, err := store.ImportCSV(logger.store, migrateFrom)
+		if err != nil {
+			log.Fatalf("Failed to import existing %s: %v", migrateFrom, err)
+		}
+		if imported > 0 {
+			fmt.Printf("Imported %d entries from %s\n", imported, migrateFrom)
+		}
+	}
+
+	var authenticator *auth.Authenticator
+	authReadRequired := false
+	if *authFlag {
+		keysFile := os.Getenv("KEYS_FILE")
+		if keysFile == "" {
+			keysFile = "keys.yaml"
+		}
+		if _, err := os.Stat(keysFile); os.IsNotExist(err) {
+			keysFile = ""
+		}
+		authenticator, err = auth.New(os.Getenv("JWT_PUBKEY"), keysFile)
+		if err != nil {
+			log.Fatalf("Failed to initialize auth: %v", err)
+		}
+		authReadRequired = *authReadFlag
+	}
+
+	server := NewServer(logger, authenticator, authReadRequired)
 
-	http.HandleFunc("/log", server.handleLog)
-	http.HandleFunc("/logs", server.handleLog)
-	http.HandleFunc("/api/logger", server.handleLog)
-	http.HandleFunc("/api/logger/stats", server.handleStats)
-	http.HandleFunc("/stats", server.handleStats)
-	http.HandleFunc("/quick", server.handleQuickLog)
-	http.HandleFunc("/health", server.handleHealth)
+	http.HandleFunc("/log", metricsMiddleware("/log", server.authMiddleware(server.handleLog, false)))
+	http.HandleFunc("/logs", metricsMiddleware("/logs", server.authMiddleware(server.handleLog, false)))
+	http.HandleFunc("/api/logger", metricsMiddleware("/api/logger", server.authMiddleware(server.handleLog, false)))
+	http.HandleFunc("/api/logger/stats", metricsMiddleware("/api/logger/stats", server.authMiddleware(server.handleStats, false)))
+	http.HandleFunc("/stats", metricsMiddleware("/stats", server.authMiddleware(server.handleStats, false)))
+	http.HandleFunc("/quick", metricsMiddleware("/quick", server.authMiddleware(server.handleQuickLog, true)))
+	http.HandleFunc("/write", metricsMiddleware("/write", server.authMiddleware(server.handleWrite, false)))
+	http.HandleFunc("/logs/stream", metricsMiddleware("/logs/stream", server.authMiddleware(server.handleLogsStream, false)))
+	http.HandleFunc("/health", metricsMiddleware("/health", server.authMiddleware(server.handleHealth, false)))
+	http.HandleFunc("/metrics", metricsMiddleware("/metrics", server.authMiddleware(server.handleMetrics, false)))
 
 	// Get local IP addresses for remote access info
 	host := os.Getenv("HOST")
@@ -535,6 +828,14 @@ func main() {
 	}
 
 	fmt.Printf("🚀 Go Logger API Server starting on %s:%s\n", host, port)
+	fmt.Printf("Storage backend: %s\n", dsn)
+	if authenticator != nil {
+		if authReadRequired {
+			fmt.Println("Auth: enabled (reads also require a credential)")
+		} else {
+			fmt.Println("Auth: enabled (writes require a credential, reads stay public)")
+		}
+	}
 	fmt.Println("Endpoints:")
 	fmt.Println("  POST   /api/logger       - Add a log entry (JSON body)")
 	fmt.Println("  GET    /api/logger       - Get log entries (with ?limit, ?offset, ?source, ?name filters)")
@@ -545,7 +846,10 @@ func main() {
 	fmt.Println("  DELETE /log              - Clear all log entries")
 	fmt.Println("  GET    /stats            - Get log statistics")
 	fmt.Println("  GET    /quick            - Quick log entry (query params: name, value, source)")
+	fmt.Println("  POST   /write            - Batched line-protocol ingest (optional gzip body)")
+	fmt.Println("  GET    /logs/stream      - Live SSE stream of new entries (?source, ?name filters)")
 	fmt.Println("  GET    /health           - Health check")
+	fmt.Println("  GET    /metrics          - Prometheus metrics (process counters + per-entry gauges)")
 
 	// Display network information for remote access
 	if host == "0.0.0.0" || host == "" {
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spk57/myAnalytics.jl/go-logger/store"
+)
+
+// sanitizeMetricName converts a LogEntry.Name into a valid Prometheus
+// metric name ([a-zA-Z_:][a-zA-Z0-9_:]*), prefixing it with "logger_" so it
+// reads as belonging to this exporter.
+func sanitizeMetricName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	sanitized := b.String()
+	if sanitized == "" {
+		sanitized = "_"
+	}
+	return "logger_" + sanitized
+}
+
+// writeEntryGauges renders one gauge per sanitized entry name, with a
+// series per (source, transaction) pair, from the latest value observed
+// for each. Entries whose value doesn't parse as a float are skipped, since
+// Prometheus gauges are numeric-only.
+func writeEntryGauges(w io.Writer, entries []store.LogEntry) {
+	type series struct {
+		source, transaction string
+		value               float64
+	}
+	byMetric := map[string][]series{}
+
+	for _, e := range entries {
+		v, err := strconv.ParseFloat(e.Value, 64)
+		if err != nil {
+			continue
+		}
+		metric := sanitizeMetricName(e.Name)
+		byMetric[metric] = append(byMetric[metric], series{source: e.Source, transaction: e.Transaction, value: v})
+	}
+
+	metricNames := make([]string, 0, len(byMetric))
+	for name := range byMetric {
+		metricNames = append(metricNames, name)
+	}
+	sort.Strings(metricNames)
+
+	for _, metric := range metricNames {
+		fmt.Fprintf(w, "# HELP %s Latest value reported for log entry %q.\n", metric, strings.TrimPrefix(metric, "logger_"))
+		fmt.Fprintf(w, "# TYPE %s gauge\n", metric)
+		for _, s := range byMetric[metric] {
+			fmt.Fprintf(w, "%s{source=%q,transaction=%q} %s\n", metric, s.source, s.transaction, strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Histogram is a lock-free, fixed-bucket histogram: Observe only ever
+// performs atomic adds and a CAS loop for the running sum, never a mutex.
+type Histogram struct {
+	buckets []float64 // ascending upper bounds, excluding +Inf
+	counts  []uint64  // per-bucket counts; counts[len(buckets)] is the +Inf bucket
+	count   uint64
+	sumBits uint64 // math.Float64bits of the running sum
+}
+
+// NewHistogram creates a Histogram with the given ascending bucket bounds.
+func NewHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)+1),
+	}
+}
+
+// Observe records a single value.
+func (h *Histogram) Observe(v float64) {
+	idx := len(h.buckets)
+	for i, b := range h.buckets {
+		if v <= b {
+			idx = i
+			break
+		}
+	}
+	atomic.AddUint64(&h.counts[idx], 1)
+	atomic.AddUint64(&h.count, 1)
+
+	for {
+		old := atomic.LoadUint64(&h.sumBits)
+		newSum := math.Float64frombits(old) + v
+		if atomic.CompareAndSwapUint64(&h.sumBits, old, math.Float64bits(newSum)) {
+			break
+		}
+	}
+}
+
+// Snapshot returns the bucket bounds, their cumulative ("le") counts, the
+// running sum and total observation count.
+func (h *Histogram) Snapshot() (buckets []float64, cumulativeCounts []uint64, sum float64, count uint64) {
+	cumulative := make([]uint64, len(h.counts))
+	var running uint64
+	for i := range h.counts {
+		running += atomic.LoadUint64(&h.counts[i])
+		cumulative[i] = running
+	}
+	return h.buckets, cumulative, math.Float64frombits(atomic.LoadUint64(&h.sumBits)), atomic.LoadUint64(&h.count)
+}
@@ -0,0 +1,109 @@
+// Package metrics collects process counters and a request-duration
+// histogram for the logger server and renders them in Prometheus text
+// exposition format, so Grafana/VictoriaMetrics can scrape the same
+// server that ingests data without running a second exporter.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter is an atomic, monotonically increasing counter.
+type Counter struct {
+	v uint64
+}
+
+func (c *Counter) Inc()          { atomic.AddUint64(&c.v, 1) }
+func (c *Counter) Add(n uint64)  { atomic.AddUint64(&c.v, n) }
+func (c *Counter) Value() uint64 { return atomic.LoadUint64(&c.v) }
+
+// CounterVec is a set of Counters keyed by a tuple of label values, e.g.
+// {path, method, code}. Looking up a key and iterating with Each take a
+// lock; incrementing an already-registered counter is lock-free.
+type CounterVec struct {
+	mu          sync.Mutex
+	counters    map[string]*Counter
+	labelValues map[string][]string
+}
+
+// NewCounterVec creates an empty CounterVec.
+func NewCounterVec() *CounterVec {
+	return &CounterVec{
+		counters:    make(map[string]*Counter),
+		labelValues: make(map[string][]string),
+	}
+}
+
+// WithLabelValues returns the Counter for this label tuple, creating it on
+// first use.
+func (cv *CounterVec) WithLabelValues(values ...string) *Counter {
+	key := fmt.Sprint(values)
+
+	cv.mu.Lock()
+	c, ok := cv.counters[key]
+	if !ok {
+		c = &Counter{}
+		cv.counters[key] = c
+		cv.labelValues[key] = values
+	}
+	cv.mu.Unlock()
+
+	return c
+}
+
+// Each calls fn once per registered label tuple with its current value.
+func (cv *CounterVec) Each(fn func(labelValues []string, value uint64)) {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	for key, c := range cv.counters {
+		fn(cv.labelValues[key], c.Value())
+	}
+}
+
+// DefaultDurationBuckets are the upper bounds (in seconds) used by
+// AddEntryDuration, tuned for an operation expected to take low
+// milliseconds against a CSV/SQLite/Postgres backend.
+var DefaultDurationBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// Package-level metrics shared across the server.
+var (
+	// EntriesTotal counts every LogEntry successfully persisted.
+	EntriesTotal = &Counter{}
+	// HTTPRequestsTotal is keyed by {path, method, code}.
+	HTTPRequestsTotal = NewCounterVec()
+	// AddEntryDuration observes how long a single AddEntry/AddEntries call
+	// takes against the configured Store.
+	AddEntryDuration = NewHistogram(DefaultDurationBuckets)
+)
+
+// WritePrometheus renders every metric above in Prometheus text exposition
+// format.
+func WritePrometheus(w io.Writer) {
+	fmt.Fprintln(w, "# HELP logger_entries_total Total log entries written.")
+	fmt.Fprintln(w, "# TYPE logger_entries_total counter")
+	fmt.Fprintf(w, "logger_entries_total %d\n", EntriesTotal.Value())
+
+	fmt.Fprintln(w, "# HELP logger_http_requests_total Total HTTP requests handled.")
+	fmt.Fprintln(w, "# TYPE logger_http_requests_total counter")
+	HTTPRequestsTotal.Each(func(lv []string, v uint64) {
+		fmt.Fprintf(w, "logger_http_requests_total{path=%q,method=%q,code=%q} %d\n", lv[0], lv[1], lv[2], v)
+	})
+
+	buckets, cumulative, sum, count := AddEntryDuration.Snapshot()
+	fmt.Fprintln(w, "# HELP logger_add_entry_duration_seconds Time spent persisting a log entry.")
+	fmt.Fprintln(w, "# TYPE logger_add_entry_duration_seconds histogram")
+	for i, b := range buckets {
+		fmt.Fprintf(w, "logger_add_entry_duration_seconds_bucket{le=%q} %d\n", formatFloat(b), cumulative[i])
+	}
+	fmt.Fprintf(w, "logger_add_entry_duration_seconds_bucket{le=\"+Inf\"} %d\n", cumulative[len(cumulative)-1])
+	fmt.Fprintf(w, "logger_add_entry_duration_seconds_sum %s\n", formatFloat(sum))
+	fmt.Fprintf(w, "logger_add_entry_duration_seconds_count %d\n", count)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	c := &Counter{}
+	c.Inc()
+	c.Add(4)
+	if got := c.Value(); got != 5 {
+		t.Errorf("Value() = %d, want 5", got)
+	}
+}
+
+func TestCounterVecWithLabelValues(t *testing.T) {
+	cv := NewCounterVec()
+	cv.WithLabelValues("/log", "GET", "200").Inc()
+	cv.WithLabelValues("/log", "GET", "200").Inc()
+	cv.WithLabelValues("/log", "POST", "201").Inc()
+
+	seen := map[string]uint64{}
+	cv.Each(func(lv []string, v uint64) {
+		seen[strings.Join(lv, ",")] = v
+	})
+
+	if seen["/log,GET,200"] != 2 {
+		t.Errorf("expected GET/200 count 2, got %d", seen["/log,GET,200"])
+	}
+	if seen["/log,POST,201"] != 1 {
+		t.Errorf("expected POST/201 count 1, got %d", seen["/log,POST,201"])
+	}
+}
+
+func TestHistogramObserveAndSnapshot(t *testing.T) {
+	h := NewHistogram([]float64{1, 5, 10})
+	h.Observe(0.5)
+	h.Observe(3)
+	h.Observe(20)
+
+	buckets, cumulative, sum, count := h.Snapshot()
+	if len(buckets) != 3 {
+		t.Fatalf("expected 3 bucket bounds, got %d", len(buckets))
+	}
+	// cumulative[i] is the count of observations <= buckets[i]; the last
+	// entry is the +Inf bucket and always equals the total count.
+	if cumulative[0] != 1 {
+		t.Errorf("expected 1 observation <= 1, got %d", cumulative[0])
+	}
+	if cumulative[1] != 2 {
+		t.Errorf("expected 2 observations <= 5, got %d", cumulative[1])
+	}
+	if cumulative[len(cumulative)-1] != count {
+		t.Errorf("expected +Inf bucket to equal total count %d, got %d", count, cumulative[len(cumulative)-1])
+	}
+	if count != 3 {
+		t.Errorf("expected count 3, got %d", count)
+	}
+	if sum != 23.5 {
+		t.Errorf("expected sum 23.5, got %v", sum)
+	}
+}
+
+func TestWritePrometheusIncludesRegisteredMetrics(t *testing.T) {
+	EntriesTotal.Add(1)
+	HTTPRequestsTotal.WithLabelValues("/write", "POST", "200").Inc()
+	AddEntryDuration.Observe(0.002)
+
+	var buf bytes.Buffer
+	WritePrometheus(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE logger_entries_total counter",
+		`logger_http_requests_total{path="/write",method="POST",code="200"}`,
+		"# TYPE logger_add_entry_duration_seconds histogram",
+		"logger_add_entry_duration_seconds_count",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spk57/myAnalytics.jl/go-logger/store"
+)
+
+func TestSanitizeMetricName(t *testing.T) {
+	cases := map[string]string{
+		"temp":         "logger_temp",
+		"temp.celsius": "logger_temp_celsius",
+		"2cold":        "logger__2cold",
+		"":             "logger__",
+	}
+	for in, want := range cases {
+		if got := sanitizeMetricName(in); got != want {
+			t.Errorf("sanitizeMetricName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWriteEntryGaugesSkipsNonNumericValues(t *testing.T) {
+	var buf bytes.Buffer
+	writeEntryGauges(&buf, []store.LogEntry{
+		{Name: "temp", Source: "arduino-1", Value: "23.5"},
+		{Name: "status", Source: "arduino-1", Value: "ok"},
+	})
+
+	out := buf.String()
+	if !strings.Contains(out, `logger_temp{source="arduino-1",transaction=""} 23.5`) {
+		t.Fatalf("expected numeric gauge in output, got:\n%s", out)
+	}
+	if strings.Contains(out, "logger_status") {
+		t.Fatalf("expected non-numeric entry to be skipped, got:\n%s", out)
+	}
+}
+
+func TestWriteEntryGaugesGroupsBySourceAndTransaction(t *testing.T) {
+	var buf bytes.Buffer
+	writeEntryGauges(&buf, []store.LogEntry{
+		{Name: "temp", Source: "arduino-1", Transaction: "room=kitchen", Value: "20"},
+		{Name: "temp", Source: "arduino-2", Value: "30"},
+	})
+
+	out := buf.String()
+	if !strings.Contains(out, `logger_temp{source="arduino-1",transaction="room=kitchen"} 20`) {
+		t.Fatalf("expected arduino-1 series in output, got:\n%s", out)
+	}
+	if !strings.Contains(out, `logger_temp{source="arduino-2",transaction=""} 30`) {
+		t.Fatalf("expected arduino-2 series in output, got:\n%s", out)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sseRetryMillis tells a reconnecting EventSource client how long to wait
+// before retrying, sent once at the start of the stream.
+const sseRetryMillis = 3000
+
+// sseHeartbeatInterval keeps intermediate proxies from closing an
+// otherwise-idle connection.
+const sseHeartbeatInterval = 15 * time.Second
+
+// handleLogsStream upgrades to text/event-stream and pushes every
+// newly-added LogEntry as a JSON "data:" event, filtered server-side by the
+// optional ?source= and ?name= query parameters. Browser dashboards and
+// "curl -N" consumers can use it to watch Arduino data in real time
+// without polling /logs.
+func (s *Server) handleLogsStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		enableCORS(w)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"success":false,"message":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, `{"success":false,"message":"streaming unsupported"}`, http.StatusInternalServerError)
+		return
+	}
+
+	enableCORS(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	query := r.URL.Query()
+	entries, cancel := s.logger.Subscribe(query.Get("source"), query.Get("name"))
+	defer cancel()
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case entry, ok := <-entries:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(entry)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
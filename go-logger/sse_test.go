@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spk57/myAnalytics.jl/go-logger/store"
+)
+
+func TestLoggerSubscribeFiltersBySourceAndName(t *testing.T) {
+	logger := &Logger{store: mustNewCSVStore(t), latest: map[string]store.LogEntry{}}
+
+	ch, cancel := logger.Subscribe("arduino-1", "temp")
+	defer cancel()
+
+	if _, err := logger.AddEntry(time.Now(), "", "temp", "1", "arduino-2", ""); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	if _, err := logger.AddEntry(time.Now(), "", "humidity", "3", "arduino-1", ""); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	if _, err := logger.AddEntry(time.Now(), "", "temp", "2", "arduino-1", ""); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	select {
+	case e := <-ch:
+		if e.Source != "arduino-1" || e.Value != "2" {
+			t.Fatalf("expected arduino-1's entry, got %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for subscribed entry")
+	}
+
+	select {
+	case e := <-ch:
+		t.Fatalf("expected no further entries, got %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestLoggerSubscribeCancelClosesChannel(t *testing.T) {
+	logger := &Logger{store: mustNewCSVStore(t), latest: map[string]store.LogEntry{}}
+
+	ch, cancel := logger.Subscribe("", "")
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("expected channel to be closed after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for channel to close")
+	}
+}
+
+func TestHandleLogsStreamSendsMatchingEntry(t *testing.T) {
+	logger := &Logger{store: mustNewCSVStore(t), latest: map[string]store.LogEntry{}}
+	server := NewServer(logger, nil, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/logs/stream?source=arduino-1", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		server.handleLogsStream(rec, req)
+		close(done)
+	}()
+
+	// Give handleLogsStream time to subscribe before publishing.
+	time.Sleep(50 * time.Millisecond)
+	if _, err := logger.AddEntry(time.Now(), "", "temp", "23.5", "arduino-1", ""); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handleLogsStream did not return after context cancellation")
+	}
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `"value":"23.5"`) {
+		t.Fatalf("expected published entry in SSE body, got %q", body)
+	}
+	if !strings.HasPrefix(body, "retry:") {
+		t.Fatalf("expected stream to open with a retry: line, got %q", body)
+	}
+}
+
+func mustNewCSVStore(t *testing.T) store.Store {
+	t.Helper()
+	s, err := store.NewCSVStore(t.TempDir() + "/logger.csv")
+	if err != nil {
+		t.Fatalf("NewCSVStore: %v", err)
+	}
+	return s
+}
@@ -0,0 +1,525 @@
+package store
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var csvHeaders = []string{"id", "transaction", "datetime", "name", "value", "source", "created_at", "principal"}
+
+// rotation holds the size/age/backup/compression thresholds that control
+// when CSVStore rotates its live file, read once at startup from
+// LOG_MAX_SIZE_MB, LOG_MAX_AGE_HOURS, LOG_MAX_BACKUPS and LOG_COMPRESS.
+// Any threshold left at its zero value is treated as "disabled".
+type rotation struct {
+	maxSizeBytes int64
+	maxAge       time.Duration
+	maxBackups   int
+	compress     bool
+}
+
+func rotationFromEnv() rotation {
+	var r rotation
+	if v := os.Getenv("LOG_MAX_SIZE_MB"); v != "" {
+		if mb, err := strconv.ParseInt(v, 10, 64); err == nil && mb > 0 {
+			r.maxSizeBytes = mb * 1024 * 1024
+		}
+	}
+	if v := os.Getenv("LOG_MAX_AGE_HOURS"); v != "" {
+		if h, err := strconv.ParseFloat(v, 64); err == nil && h > 0 {
+			r.maxAge = time.Duration(h * float64(time.Hour))
+		}
+	}
+	if v := os.Getenv("LOG_MAX_BACKUPS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			r.maxBackups = n
+		}
+	}
+	r.compress = isEnabled(os.Getenv("LOG_COMPRESS"))
+	return r
+}
+
+func isEnabled(v string) bool {
+	return v == "true" || v == "1" || v == "yes"
+}
+
+// CSVStore is the original flat-file backend. All entries are kept in an
+// in-memory cache protected by mu; the cache is populated once at startup
+// from the live file plus any rotated backups, and every write appends to
+// both the cache and the live file, so reads never re-parse anything from
+// disk.
+//
+// When rotation is configured, the live file is periodically renamed to a
+// timestamped backup (optionally gzipped) and a fresh file is started in
+// its place; NewCSVStore transparently merges the live file with every
+// backup it finds next to it.
+type CSVStore struct {
+	mu       sync.RWMutex
+	filePath string
+	entries  []LogEntry
+	nextID   int
+
+	rotation rotation
+}
+
+// NewCSVStore opens (creating if necessary) the CSV file at filePath, and
+// configures rotation from the environment.
+func NewCSVStore(filePath string) (*CSVStore, error) {
+	s := &CSVStore{filePath: filePath, nextID: 1, rotation: rotationFromEnv()}
+	if err := s.initFile(); err != nil {
+		return nil, err
+	}
+	entries, err := s.readAllEntries()
+	if err != nil {
+		return nil, err
+	}
+	s.entries = entries
+	for _, e := range entries {
+		if e.ID >= s.nextID {
+			s.nextID = e.ID + 1
+		}
+	}
+	return s, nil
+}
+
+func (s *CSVStore) initFile() error {
+	if _, err := os.Stat(s.filePath); os.IsNotExist(err) {
+		file, err := os.Create(s.filePath)
+		if err != nil {
+			return fmt.Errorf("failed to create log file: %w", err)
+		}
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		if err := writer.Write(csvHeaders); err != nil {
+			return fmt.Errorf("failed to write headers: %w", err)
+		}
+		writer.Flush()
+	}
+	return nil
+}
+
+// backupBase returns the directory and extension-less basename used to
+// recognize this store's rotated files, e.g. "/data" and "logger" for
+// filePath "/data/logger.csv".
+func (s *CSVStore) backupBase() (dir, base string) {
+	dir = filepath.Dir(s.filePath)
+	base = strings.TrimSuffix(filepath.Base(s.filePath), filepath.Ext(s.filePath))
+	return dir, base
+}
+
+// backupPaths lists every rotated backup for this store (plain and
+// gzipped), oldest first; their names sort chronologically since they
+// embed an RFC3339 timestamp.
+func (s *CSVStore) backupPaths() []string {
+	dir, base := s.backupBase()
+	csvMatches, _ := filepath.Glob(filepath.Join(dir, base+"-*.csv"))
+	gzMatches, _ := filepath.Glob(filepath.Join(dir, base+"-*.csv.gz"))
+	all := append(csvMatches, gzMatches...)
+	sort.Strings(all)
+	return all
+}
+
+// readAllEntries merges the live file with every backup found next to it,
+// sorted by Datetime, so rotation never changes what a reader sees.
+func (s *CSVStore) readAllEntries() ([]LogEntry, error) {
+	paths := append(s.backupPaths(), s.filePath)
+
+	var all []LogEntry
+	for _, p := range paths {
+		entries, err := readCSVFile(p)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, entries...)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Datetime.Before(all[j].Datetime) })
+	return all, nil
+}
+
+// readCSVFile parses a single CSV file, transparently gunzipping it if its
+// name ends in ".gz".
+func readCSVFile(path string) ([]LogEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzipped backup %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // older files may predate the "principal" column
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LogEntry
+	for i, record := range records {
+		if i == 0 { // skip header
+			continue
+		}
+		if len(record) < 7 {
+			continue
+		}
+
+		id, _ := strconv.Atoi(record[0])
+		datetime, _ := time.Parse(time.RFC3339, record[2])
+		createdAt, _ := time.Parse(time.RFC3339, record[6])
+
+		var principal string
+		if len(record) >= 8 {
+			principal = record[7]
+		}
+
+		entries = append(entries, LogEntry{
+			ID:          id,
+			Transaction: record[1],
+			Datetime:    datetime,
+			Name:        record[3],
+			Value:       record[4],
+			Source:      record[5],
+			CreatedAt:   createdAt,
+			Principal:   principal,
+		})
+	}
+	return entries, nil
+}
+
+// csvRecord renders e in the same column order as csvHeaders.
+func csvRecord(e LogEntry) []string {
+	return []string{
+		strconv.Itoa(e.ID),
+		e.Transaction,
+		e.Datetime.Format(time.RFC3339),
+		e.Name,
+		e.Value,
+		e.Source,
+		e.CreatedAt.Format(time.RFC3339),
+		e.Principal,
+	}
+}
+
+func (s *CSVStore) appendRecord(e LogEntry) error {
+	return s.appendRecords([]LogEntry{e})
+}
+
+// appendRecords opens the live file once and writes every entry through a
+// single csv.Writer, flushing once at the end, so a batch of N entries
+// costs one open/flush/close instead of N.
+func (s *CSVStore) appendRecords(entries []LogEntry) error {
+	file, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	for _, e := range entries {
+		if err := writer.Write(csvRecord(e)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// AddEntry appends a single entry under one lock acquisition and flush.
+func (s *CSVStore) AddEntry(e LogEntry) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addLocked(e)
+}
+
+// addLocked assigns an ID and appends e. Callers must hold mu.
+func (s *CSVStore) addLocked(e LogEntry) (int, error) {
+	// Check before writing: appendRecord's own write would otherwise touch
+	// the live file's mtime to "now" right before rotateIfNeeded reads it,
+	// making LOG_MAX_AGE_HOURS impossible to ever cross.
+	if err := s.rotateIfNeeded(); err != nil {
+		return 0, err
+	}
+
+	e.ID = s.nextID
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	if err := s.appendRecord(e); err != nil {
+		return 0, err
+	}
+	s.entries = append(s.entries, e)
+	s.nextID++
+	return e.ID, nil
+}
+
+// rotateIfNeeded renames the live file to a timestamped backup and starts a
+// fresh one once it crosses LOG_MAX_SIZE_MB or LOG_MAX_AGE_HOURS, judged
+// from a single os.Stat of the live file so age survives process restarts
+// instead of resetting every time the server starts. Callers must hold mu.
+func (s *CSVStore) rotateIfNeeded() error {
+	if s.rotation.maxSizeBytes == 0 && s.rotation.maxAge == 0 {
+		return nil
+	}
+
+	info, err := os.Stat(s.filePath)
+	if err != nil {
+		return nil
+	}
+
+	due := false
+	if s.rotation.maxSizeBytes > 0 && info.Size() >= s.rotation.maxSizeBytes {
+		due = true
+	}
+	if !due && s.rotation.maxAge > 0 && time.Since(info.ModTime()) >= s.rotation.maxAge {
+		due = true
+	}
+	if !due {
+		return nil
+	}
+
+	dir, base := s.backupBase()
+	// Fixed-width nanoseconds keep two rotations in the same second from
+	// sharing (and overwriting) one backup name, and keep names sorting
+	// chronologically.
+	backupPath := filepath.Join(dir, fmt.Sprintf("%s-%s.csv", base, time.Now().UTC().Format("2006-01-02T15:04:05.000000000Z07:00")))
+	if err := os.Rename(s.filePath, backupPath); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := s.initFile(); err != nil {
+		return fmt.Errorf("failed to start new log file after rotation: %w", err)
+	}
+
+	if s.rotation.compress {
+		go compressBackup(backupPath)
+	}
+	s.pruneBackups()
+	return nil
+}
+
+// compressBackup gzips path and removes the uncompressed original. It runs
+// in its own goroutine so a slow compression never blocks an AddEntry call;
+// failures are logged rather than surfaced, since the uncompressed backup
+// is still valid and readable either way.
+func compressBackup(path string) {
+	src, err := os.Open(path)
+	if err != nil {
+		log.Printf("logger: failed to open %s for compression: %v", path, err)
+		return
+	}
+	defer src.Close()
+
+	dstPath := path + ".gz"
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		log.Printf("logger: failed to create %s: %v", dstPath, err)
+		return
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		log.Printf("logger: failed to compress %s: %v", path, err)
+		gz.Close()
+		dst.Close()
+		os.Remove(dstPath)
+		return
+	}
+	if err := gz.Close(); err != nil {
+		log.Printf("logger: failed to finalize %s: %v", dstPath, err)
+	}
+	dst.Close()
+
+	if err := os.Remove(path); err != nil {
+		log.Printf("logger: failed to remove uncompressed backup %s: %v", path, err)
+	}
+}
+
+// pruneBackups deletes the oldest backups once there are more than
+// LOG_MAX_BACKUPS of them. Callers must hold mu.
+func (s *CSVStore) pruneBackups() {
+	if s.rotation.maxBackups <= 0 {
+		return
+	}
+	backups := s.backupPaths()
+	excess := len(backups) - s.rotation.maxBackups
+	for i := 0; i < excess; i++ {
+		if err := os.Remove(backups[i]); err != nil {
+			log.Printf("logger: failed to prune backup %s: %v", backups[i], err)
+		}
+	}
+}
+
+// AddEntries writes all rows under a single lock acquisition and a single
+// appendRecords flush, so a large batch costs one file open/write/close
+// instead of one per entry.
+func (s *CSVStore) AddEntries(entries []LogEntry) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	if err := s.rotateIfNeeded(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(entries))
+	assigned := make([]LogEntry, 0, len(entries))
+	for _, e := range entries {
+		e.ID = s.nextID
+		if e.CreatedAt.IsZero() {
+			e.CreatedAt = time.Now()
+		}
+		assigned = append(assigned, e)
+		ids = append(ids, e.ID)
+		s.nextID++
+	}
+
+	if err := s.appendRecords(assigned); err != nil {
+		// appendRecords failed before any of assigned reached s.entries, so
+		// none of these ids were actually persisted; returning them here
+		// would tell the caller writes succeeded that never happened.
+		return nil, err
+	}
+	s.entries = append(s.entries, assigned...)
+	return ids, nil
+}
+
+// Query applies filtering and pagination over the in-memory cache.
+func (s *CSVStore) Query(q Query) ([]LogEntry, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var filtered []LogEntry
+	for _, e := range s.entries {
+		if q.Source != "" && e.Source != q.Source {
+			continue
+		}
+		if q.Name != "" && e.Name != q.Name {
+			continue
+		}
+		if !q.From.IsZero() && e.Datetime.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && e.Datetime.After(q.To) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+
+	total := len(filtered)
+
+	limit, offset := q.Limit, q.Offset
+	if offset >= total {
+		return []LogEntry{}, total, nil
+	}
+	end := offset + limit
+	if limit <= 0 || end > total {
+		end = total
+	}
+
+	return filtered[offset:end], total, nil
+}
+
+// Clear truncates the live file back to just its header row and deletes
+// every rotated backup when called with no sources. When one or more
+// sources are given, only entries matching them are removed: since a
+// rotated backup can't be edited in place, every backup is folded into the
+// fresh live file along with whatever live entries survive the filter.
+func (s *CSVStore) Clear(sources ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var retained []LogEntry
+	if len(sources) > 0 {
+		allowed := make(map[string]bool, len(sources))
+		for _, src := range sources {
+			allowed[src] = true
+		}
+		for _, e := range s.entries {
+			if !allowed[e.Source] {
+				retained = append(retained, e)
+			}
+		}
+	}
+
+	file, err := os.Create(s.filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(csvHeaders); err != nil {
+		return err
+	}
+	for _, e := range retained {
+		if err := writer.Write(csvRecord(e)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+
+	for _, backup := range s.backupPaths() {
+		if err := os.Remove(backup); err != nil {
+			return fmt.Errorf("failed to remove backup %s: %w", backup, err)
+		}
+	}
+
+	s.entries = retained
+	if len(retained) == 0 {
+		s.nextID = 1
+	}
+	return nil
+}
+
+// Stats summarizes the entries currently cached.
+func (s *CSVStore) Stats() (Stats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sourcesMap := make(map[string]bool)
+	namesMap := make(map[string]bool)
+	for _, e := range s.entries {
+		sourcesMap[e.Source] = true
+		namesMap[e.Name] = true
+	}
+
+	sources := make([]string, 0, len(sourcesMap))
+	for s := range sourcesMap {
+		sources = append(sources, s)
+	}
+	names := make([]string, 0, len(namesMap))
+	for n := range namesMap {
+		names = append(names, n)
+	}
+
+	return Stats{
+		TotalEntries: len(s.entries),
+		Sources:      sources,
+		Names:        names,
+	}, nil
+}
+
+// Close is a no-op for the CSV backend; the file is opened per-operation.
+func (s *CSVStore) Close() error { return nil }
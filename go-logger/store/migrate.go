@@ -0,0 +1,42 @@
+package store
+
+import "os"
+
+// ImportCSV bulk-loads every row of an existing CSV-format log file into
+// dst, preserving their original order. It is used to migrate a
+// pre-existing "logger.csv" into a freshly configured SQLite or Postgres
+// backend on first start, and is a no-op if csvPath does not exist or if
+// dst already holds entries, so restarting the process doesn't re-import
+// (and duplicate) the same rows every time.
+func ImportCSV(dst Store, csvPath string) (int, error) {
+	if _, err := os.Stat(csvPath); os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	stats, err := dst.Stats()
+	if err != nil {
+		return 0, err
+	}
+	if stats.TotalEntries > 0 {
+		return 0, nil
+	}
+
+	src, err := NewCSVStore(csvPath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	entries, _, err := src.Query(Query{})
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	if _, err := dst.AddEntries(entries); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
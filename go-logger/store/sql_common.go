@@ -0,0 +1,224 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sqlStore backs both the SQLite and Postgres implementations: the schema
+// and query shapes are identical, only the placeholder syntax and a couple
+// of DDL keywords differ between the two drivers.
+type sqlStore struct {
+	db *sql.DB
+	// placeholder returns the bind-parameter marker for the n-th (1-indexed)
+	// argument, e.g. "?" for SQLite or "$1" for Postgres.
+	placeholder func(n int) string
+	// returningID reports whether the driver supports "INSERT ... RETURNING
+	// id" (Postgres) instead of sql.Result.LastInsertId (SQLite).
+	returningID bool
+}
+
+func (s *sqlStore) createSchema(autoIncrementPK string) error {
+	_, err := s.db.Exec(fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS log_entries (
+			id %s,
+			transaction_id TEXT NOT NULL,
+			datetime TIMESTAMP NOT NULL,
+			name TEXT NOT NULL,
+			value TEXT NOT NULL,
+			source TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			principal TEXT NOT NULL DEFAULT ''
+		)`, autoIncrementPK))
+	if err != nil {
+		return fmt.Errorf("failed to create log_entries table: %w", err)
+	}
+
+	_, err = s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_log_entries_source_name_datetime
+		ON log_entries (source, name, datetime)`)
+	if err != nil {
+		return fmt.Errorf("failed to create time-series index: %w", err)
+	}
+	return nil
+}
+
+func (s *sqlStore) AddEntry(e LogEntry) (int, error) {
+	ids, err := s.AddEntries([]LogEntry{e})
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// AddEntries inserts all rows within a single transaction.
+func (s *sqlStore) AddEntries(entries []LogEntry) ([]int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(
+		`INSERT INTO log_entries (transaction_id, datetime, name, value, source, created_at, principal)
+		 VALUES (%s, %s, %s, %s, %s, %s, %s)`,
+		s.placeholder(1), s.placeholder(2), s.placeholder(3),
+		s.placeholder(4), s.placeholder(5), s.placeholder(6), s.placeholder(7))
+	if s.returningID {
+		query += " RETURNING id"
+	}
+
+	ids := make([]int, 0, len(entries))
+	for _, e := range entries {
+		if e.CreatedAt.IsZero() {
+			e.CreatedAt = time.Now()
+		}
+		var id int64
+		if s.returningID {
+			if err := tx.QueryRow(query, e.Transaction, e.Datetime, e.Name, e.Value, e.Source, e.CreatedAt, e.Principal).Scan(&id); err != nil {
+				return nil, err
+			}
+		} else {
+			res, err := tx.Exec(query, e.Transaction, e.Datetime, e.Name, e.Value, e.Source, e.CreatedAt, e.Principal)
+			if err != nil {
+				return nil, err
+			}
+			id, err = res.LastInsertId()
+			if err != nil {
+				return nil, err
+			}
+		}
+		ids = append(ids, int(id))
+	}
+
+	// On any failure the transaction is rolled back, so no ids are returned.
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (s *sqlStore) Query(q Query) ([]LogEntry, int, error) {
+	var conds []string
+	var args []interface{}
+
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, s.placeholder(len(args))))
+	}
+	if q.Source != "" {
+		add("source = %s", q.Source)
+	}
+	if q.Name != "" {
+		add("name = %s", q.Name)
+	}
+	if !q.From.IsZero() {
+		add("datetime >= %s", q.From)
+	}
+	if !q.To.IsZero() {
+		add("datetime <= %s", q.To)
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM log_entries %s", where)
+	if err := s.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit, offset := q.Limit, q.Offset
+	if limit <= 0 {
+		limit = total
+	}
+	selectQuery := fmt.Sprintf(
+		`SELECT id, transaction_id, datetime, name, value, source, created_at, principal
+		 FROM log_entries %s ORDER BY datetime ASC LIMIT %s OFFSET %s`,
+		where, s.placeholder(len(args)+1), s.placeholder(len(args)+2))
+	rows, err := s.db.Query(selectQuery, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	// Rows are read from the driver one at a time, but every row of the
+	// requested page is collected into the returned slice; with Limit <= 0
+	// the page is the whole filtered result. No streaming iterator is offered.
+	var entries []LogEntry
+	for rows.Next() {
+		var e LogEntry
+		if err := rows.Scan(&e.ID, &e.Transaction, &e.Datetime, &e.Name, &e.Value, &e.Source, &e.CreatedAt, &e.Principal); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	if entries == nil {
+		entries = []LogEntry{}
+	}
+
+	return entries, total, nil
+}
+
+func (s *sqlStore) Clear(sources ...string) error {
+	if len(sources) == 0 {
+		_, err := s.db.Exec("DELETE FROM log_entries")
+		return err
+	}
+
+	placeholders := make([]string, len(sources))
+	args := make([]interface{}, len(sources))
+	for i, src := range sources {
+		placeholders[i] = s.placeholder(i + 1)
+		args[i] = src
+	}
+	query := fmt.Sprintf("DELETE FROM log_entries WHERE source IN (%s)", strings.Join(placeholders, ", "))
+	_, err := s.db.Exec(query, args...)
+	return err
+}
+
+func (s *sqlStore) Stats() (Stats, error) {
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM log_entries").Scan(&total); err != nil {
+		return Stats{}, err
+	}
+
+	sources, err := s.distinct("source")
+	if err != nil {
+		return Stats{}, err
+	}
+	names, err := s.distinct("name")
+	if err != nil {
+		return Stats{}, err
+	}
+
+	return Stats{TotalEntries: total, Sources: sources, Names: names}, nil
+}
+
+func (s *sqlStore) distinct(column string) ([]string, error) {
+	rows, err := s.db.Query(fmt.Sprintf("SELECT DISTINCT %s FROM log_entries", column))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]string, 0)
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,35 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+)
+
+// NewSQLiteStore opens (creating if necessary) a SQLite database at path.
+func NewSQLiteStore(path string) (Store, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create sqlite data directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	s := &sqlStore{
+		db:          db,
+		placeholder: func(n int) string { return "?" },
+		returningID: false,
+	}
+	if err := s.createSchema("INTEGER PRIMARY KEY AUTOINCREMENT"); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestCSVStore(t *testing.T) (*CSVStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "logger.csv")
+	s, err := NewCSVStore(path)
+	if err != nil {
+		t.Fatalf("NewCSVStore: %v", err)
+	}
+	return s, path
+}
+
+func TestCSVStoreAddAndQuery(t *testing.T) {
+	s, _ := newTestCSVStore(t)
+
+	if _, err := s.AddEntry(LogEntry{Name: "temp", Value: "1", Source: "arduino-1", Datetime: time.Now()}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	if _, err := s.AddEntry(LogEntry{Name: "temp", Value: "2", Source: "arduino-2", Datetime: time.Now()}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	entries, total, err := s.Query(Query{Source: "arduino-1"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if total != 1 || len(entries) != 1 || entries[0].Source != "arduino-1" {
+		t.Fatalf("expected 1 matching entry, got %+v (total %d)", entries, total)
+	}
+}
+
+func TestCSVStoreAddEntriesBatch(t *testing.T) {
+	s, _ := newTestCSVStore(t)
+
+	ids, err := s.AddEntries([]LogEntry{
+		{Name: "temp", Value: "1", Source: "a"},
+		{Name: "temp", Value: "2", Source: "b"},
+		{Name: "temp", Value: "3", Source: "a"},
+	})
+	if err != nil {
+		t.Fatalf("AddEntries: %v", err)
+	}
+	if len(ids) != 3 || ids[0] == ids[1] || ids[1] == ids[2] {
+		t.Fatalf("expected 3 distinct ids, got %v", ids)
+	}
+
+	stats, err := s.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.TotalEntries != 3 {
+		t.Fatalf("expected 3 entries, got %d", stats.TotalEntries)
+	}
+}
+
+func TestCSVStoreClearScopedToSource(t *testing.T) {
+	s, _ := newTestCSVStore(t)
+
+	if _, err := s.AddEntry(LogEntry{Name: "temp", Value: "1", Source: "arduino-1"}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	if _, err := s.AddEntry(LogEntry{Name: "temp", Value: "2", Source: "arduino-2"}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	if err := s.Clear("arduino-1"); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+
+	stats, err := s.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.TotalEntries != 1 || stats.Sources[0] != "arduino-2" {
+		t.Fatalf("expected only arduino-2 to survive, got %+v", stats)
+	}
+}
+
+func TestCSVStoreClearFullWipe(t *testing.T) {
+	s, _ := newTestCSVStore(t)
+
+	if _, err := s.AddEntry(LogEntry{Name: "temp", Value: "1", Source: "arduino-1"}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	if err := s.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+
+	stats, err := s.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.TotalEntries != 0 {
+		t.Fatalf("expected 0 entries after full wipe, got %d", stats.TotalEntries)
+	}
+}
+
+func TestCSVStoreRotationMergesBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logger.csv")
+	os.Setenv("LOG_MAX_SIZE_MB", "")
+	s, err := NewCSVStore(path)
+	if err != nil {
+		t.Fatalf("NewCSVStore: %v", err)
+	}
+	s.rotation.maxSizeBytes = 1 // force rotation on the very next write
+
+	if _, err := s.AddEntry(LogEntry{Name: "temp", Value: "1", Source: "a"}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	if _, err := s.AddEntry(LogEntry{Name: "temp", Value: "2", Source: "a"}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	backups := s.backupPaths()
+	if len(backups) == 0 {
+		t.Fatalf("expected at least one rotated backup, found none")
+	}
+
+	// A fresh CSVStore over the same path should transparently merge the
+	// live file with every backup it finds next to it.
+	reopened, err := NewCSVStore(path)
+	if err != nil {
+		t.Fatalf("NewCSVStore (reopen): %v", err)
+	}
+	stats, err := reopened.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.TotalEntries != 2 {
+		t.Fatalf("expected 2 entries merged across live file + backups, got %d", stats.TotalEntries)
+	}
+}
+
+func TestSQLiteStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer s.Close()
+
+	if _, err := s.AddEntry(LogEntry{Name: "temp", Value: "1", Source: "arduino-1", Datetime: time.Now()}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+	ids, err := s.AddEntries([]LogEntry{
+		{Name: "temp", Value: "2", Source: "arduino-2", Datetime: time.Now()},
+		{Name: "temp", Value: "3", Source: "arduino-2", Datetime: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("AddEntries: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("expected 2 ids, got %d", len(ids))
+	}
+
+	entries, total, err := s.Query(Query{Source: "arduino-2"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if total != 2 || len(entries) != 2 {
+		t.Fatalf("expected 2 matching entries, got %d (total %d)", len(entries), total)
+	}
+
+	if err := s.Clear("arduino-2"); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	stats, err := s.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.TotalEntries != 1 || stats.Sources[0] != "arduino-1" {
+		t.Fatalf("expected only arduino-1 to survive scoped Clear, got %+v", stats)
+	}
+}
+
+func TestImportCSVIsIdempotent(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "logger.csv")
+	src, err := NewCSVStore(csvPath)
+	if err != nil {
+		t.Fatalf("NewCSVStore: %v", err)
+	}
+	if _, err := src.AddEntry(LogEntry{Name: "temp", Value: "1", Source: "arduino-1", Datetime: time.Now()}); err != nil {
+		t.Fatalf("AddEntry: %v", err)
+	}
+
+	dstPath := filepath.Join(t.TempDir(), "data.db")
+	dst, err := NewSQLiteStore(dstPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer dst.Close()
+
+	imported, err := ImportCSV(dst, csvPath)
+	if err != nil {
+		t.Fatalf("ImportCSV: %v", err)
+	}
+	if imported != 1 {
+		t.Fatalf("expected 1 imported entry, got %d", imported)
+	}
+
+	imported, err = ImportCSV(dst, csvPath)
+	if err != nil {
+		t.Fatalf("ImportCSV (second run): %v", err)
+	}
+	if imported != 0 {
+		t.Fatalf("expected second ImportCSV to be a no-op, imported %d", imported)
+	}
+
+	stats, err := dst.Stats()
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.TotalEntries != 1 {
+		t.Fatalf("expected a restart to not duplicate rows, got %d entries", stats.TotalEntries)
+	}
+}